@@ -0,0 +1,283 @@
+package hub
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var updatesBucket = []byte("updates")
+
+// BoltTransport is a Transport implementation backed by a BoltDB file, so that
+// history survives hub restarts. It delegates live fan-out to an embedded
+// LocalTransport and only uses Bolt to persist and replay history.
+type BoltTransport struct {
+	db    *bolt.DB
+	local *LocalTransport
+
+	size int
+	ttl  time.Duration
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// boltEntry is what's actually stored under each sequence key: the update
+// plus the time it was stored, so size/ttl eviction can prune the bucket the
+// same way LocalTransport prunes its in-memory history.
+type boltEntry struct {
+	Update  Update
+	StoreAt time.Time
+}
+
+// NewBoltTransport opens (creating if needed) the BoltDB file at path and returns a
+// Transport persisting at most size updates, evicting anything older than ttl.
+// A size or ttl of 0 means unbounded, matching NewLocalTransport.
+func NewBoltTransport(path string, size int, ttl time.Duration) (*BoltTransport, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(updatesBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &BoltTransport{db: db, local: NewLocalTransport(size, ttl), size: size, ttl: ttl}, nil
+}
+
+// Dispatch implements Transport.
+//
+// The whole persist-then-fan-out sequence runs under mu, held for as long as
+// Subscribe holds it to register a live subscription and snapshot the last
+// persisted sequence (see Subscribe). That shared lock is what guarantees
+// every update is delivered exactly once across a reconnect: Subscribe either
+// observes this Dispatch call fully done (so the update is in Bolt and only
+// reaches the new subscriber via the history scan) or not yet started (so it
+// reaches the new subscriber only via the live channel registered beforehand).
+func (t *BoltTransport) Dispatch(update *Update) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return ErrClosedTransport
+	}
+
+	if err := t.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(updatesBucket)
+
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		update.ID = sequenceToEventID(seq)
+
+		data, err := json.Marshal(boltEntry{Update: *update, StoreAt: time.Now()})
+		if err != nil {
+			return err
+		}
+
+		if err := b.Put(sequenceToKey(seq), data); err != nil {
+			return err
+		}
+
+		return t.evictLocked(b)
+	}); err != nil {
+		return err
+	}
+
+	return t.local.dispatchWithID(update)
+}
+
+// evictLocked removes bucket entries past t.size or older than t.ttl, oldest
+// first. It must run inside the same transaction as the Put above, so a
+// reader never observes more entries than the configured bounds allow. Must
+// be called with t.mu held (Dispatch already holds it for the whole
+// persist-then-fan-out sequence).
+func (t *BoltTransport) evictLocked(b *bolt.Bucket) error {
+	if t.size > 0 {
+		// b.Stats() only counts keys already committed, not this transaction's
+		// own Put and Deletes, so count the keys with a cursor instead.
+		n := 0
+		c := b.Cursor()
+		for k, _ := c.First(); k != nil; k, _ = c.Next() {
+			n++
+		}
+
+		for ; n > t.size; n-- {
+			c := b.Cursor()
+			k, _ := c.First()
+			if k == nil {
+				break
+			}
+
+			if err := c.Delete(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if t.ttl > 0 {
+		cutoff := time.Now().Add(-t.ttl)
+		c := b.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			var entry boltEntry
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return err
+			}
+
+			if entry.StoreAt.After(cutoff) {
+				break
+			}
+
+			if err := c.Delete(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Subscribe implements Transport.
+func (t *BoltTransport) Subscribe(ctx context.Context, lastEventID string, topics *TopicMatcher, targets []string) (<-chan *Update, error) {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil, ErrClosedTransport
+	}
+
+	updateChan, err := t.local.Subscribe(ctx, "", topics, targets)
+	if err != nil {
+		t.mu.Unlock()
+		return nil, err
+	}
+
+	if lastEventID == "" {
+		t.mu.Unlock()
+		return updateChan, nil
+	}
+
+	fromSeq, err := eventIDToSequence(lastEventID)
+	if err != nil {
+		// Unknown or malformed IDs can't be matched to a Bolt sequence, so, like
+		// LocalTransport (which only replays starting from an exact history
+		// match), replay nothing rather than guessing — notably not defaulting
+		// to sequence 0, which would dump the entire backlog.
+		t.mu.Unlock()
+		return updateChan, nil
+	}
+
+	// Snapshot the last sequence persisted so far while still holding mu, the
+	// same lock Dispatch holds across its own persist-then-fan-out. Everything
+	// up to this sequence is already in Bolt and will be found by the scan
+	// below; everything after it is guaranteed to reach the live channel
+	// registered above instead, since such a Dispatch can't even start
+	// persisting until this call releases mu. That keeps the scan and the live
+	// channel from ever overlapping, so nothing is delivered twice.
+	var liveFromSeq uint64
+	if err := t.db.View(func(tx *bolt.Tx) error {
+		liveFromSeq = tx.Bucket(updatesBucket).Sequence()
+		return nil
+	}); err != nil {
+		t.mu.Unlock()
+		return nil, err
+	}
+	t.mu.Unlock()
+
+	replayed := make(chan *Update)
+	go func() {
+		defer close(replayed)
+
+		fromKey := sequenceToKey(fromSeq)
+		toKey := sequenceToKey(liveFromSeq)
+		if err := t.db.View(func(tx *bolt.Tx) error {
+			c := tx.Bucket(updatesBucket).Cursor()
+			for k, v := c.Seek(fromKey); k != nil && string(k) <= string(toKey); k, v = c.Next() {
+				if string(k) <= string(fromKey) {
+					continue
+				}
+
+				var entry boltEntry
+				if err := json.Unmarshal(v, &entry); err != nil {
+					return err
+				}
+
+				if !isAuthorized(targets, entry.Update.Targets) || !isSubscribedToUpdate(topics, entry.Update.Topics) {
+					continue
+				}
+
+				select {
+				case replayed <- &entry.Update:
+				case <-ctx.Done():
+					return nil
+				}
+			}
+
+			return nil
+		}); err != nil {
+			return
+		}
+
+		for update := range updateChan {
+			select {
+			case replayed <- update:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return replayed, nil
+}
+
+// Close implements Transport.
+func (t *BoltTransport) Close() error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil
+	}
+	t.closed = true
+	t.mu.Unlock()
+
+	t.local.Close()
+	return t.db.Close()
+}
+
+// sequenceToKey encodes seq as a big-endian byte string, so that Bolt's natural
+// byte-order key iteration also gives us insertion order.
+func sequenceToKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
+
+// sequenceToEventID renders seq as the decimal event ID exposed to subscribers,
+// so it can be sent back verbatim as a Last-Event-ID header value.
+func sequenceToEventID(seq uint64) string {
+	return strconv.FormatUint(seq, 10)
+}
+
+// eventIDToSequence parses an event ID into the Bolt sequence it was assigned
+// from. EarliestLastEventID maps to 0 (replay everything), matching
+// LocalTransport's own sentinel handling. Anything else that isn't one of
+// sequenceToEventID's own outputs is an error, rather than silently being
+// treated as sequence 0 (and so replaying the entire history).
+func eventIDToSequence(id string) (uint64, error) {
+	if id == EarliestLastEventID {
+		return 0, nil
+	}
+
+	return strconv.ParseUint(id, 10, 64)
+}
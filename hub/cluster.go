@@ -0,0 +1,159 @@
+package hub
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ClusterBus fans updates out across every hub instance in a cluster, so that
+// horizontal scaling doesn't require sticky routing: a publisher can hit any
+// node and every node still applies isAuthorized/isSubscribedToUpdate locally
+// and delivers to its own subscribers.
+type ClusterBus interface {
+	// Publish broadcasts update to every node subscribed to the bus, including
+	// the publishing node itself, and sets update.ID to the ID the underlying
+	// broker assigned it.
+	//
+	// The ID must be assigned atomically as part of publishing, not by a
+	// separate preceding call: otherwise two nodes racing to publish could have
+	// their updates land on the bus in an order that doesn't match their ID
+	// order, which would break Last-Event-ID replay (it assumes history order
+	// and ID order agree). Backends with an append-only log primitive that
+	// hands out monotonic offsets at write time (Redis stream IDs, JetStream
+	// sequence numbers) get this for free by using that offset as the ID.
+	Publish(update *Update) error
+
+	// Subscribe calls handler for every update published to the bus, in the
+	// order the broker assigned them, until ctx is done or the bus is closed.
+	Subscribe(ctx context.Context, handler func(*Update)) error
+
+	// History returns every update published with an ID strictly greater than
+	// afterID and at most upToID, in broker order, fetched straight from the
+	// broker rather than from any one node's local buffer. ClusterTransport
+	// uses it to replay backlog a node never saw live: one that just started,
+	// or a reconnect that happens to land on a different node than before. An
+	// empty afterID means replay from the start of retained history; an empty
+	// upToID means there's nothing to fetch.
+	History(ctx context.Context, afterID, upToID string) ([]*Update, error)
+
+	// Close closes the bus.
+	Close() error
+}
+
+// ClusterTransport is a Transport that distributes updates across every hub
+// instance via a ClusterBus, while delegating local fan-out to an embedded
+// LocalTransport fed by the bus. Last-Event-ID replay is served from the bus's
+// own history, not from the LocalTransport's buffer.
+type ClusterTransport struct {
+	bus   ClusterBus
+	local *LocalTransport
+
+	mu         sync.Mutex
+	lastSeenID string
+}
+
+// NewClusterTransport creates a ClusterTransport publishing to and consuming from
+// bus, keeping at most size updates in the local history, evicted after ttl.
+func NewClusterTransport(ctx context.Context, bus ClusterBus, size int, ttl time.Duration) (*ClusterTransport, error) {
+	t := &ClusterTransport{
+		bus:   bus,
+		local: NewLocalTransport(size, ttl),
+	}
+
+	if err := bus.Subscribe(ctx, func(update *Update) {
+		// lastSeenID is updated under the same lock Subscribe snapshots it
+		// under below, so a reconnecting subscriber's view of "what's already
+		// local" and "what to fetch from the bus instead" never overlap or
+		// leave a gap, the same way BoltTransport's mu pairs persisting an
+		// update with registering a live subscriber.
+		t.mu.Lock()
+		t.lastSeenID = update.ID
+		t.local.dispatchWithID(update)
+		t.mu.Unlock()
+	}); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Dispatch implements Transport.
+func (t *ClusterTransport) Dispatch(update *Update) error {
+	select {
+	case <-t.local.closed:
+		return ErrClosedTransport
+	default:
+	}
+
+	return t.bus.Publish(update)
+}
+
+// Subscribe implements Transport. Live updates come from the embedded
+// LocalTransport, kept up to date by the cluster bus subscription above. When
+// a Last-Event-ID is given, the backlog after it is replayed straight from the
+// bus rather than from the local buffer, so a subscriber whose previous
+// connection was to a different node gets the same replay as on that node.
+func (t *ClusterTransport) Subscribe(ctx context.Context, lastEventID string, topics *TopicMatcher, targets []string) (<-chan *Update, error) {
+	if lastEventID == "" {
+		return t.local.Subscribe(ctx, "", topics, targets)
+	}
+
+	t.mu.Lock()
+	updateChan, err := t.local.Subscribe(ctx, "", topics, targets)
+	if err != nil {
+		t.mu.Unlock()
+		return nil, err
+	}
+
+	// Snapshot the last ID this node has seen from the bus while still holding
+	// mu. Everything up to it is fetched from the bus's own history below;
+	// everything after it is guaranteed to reach the live channel registered
+	// above instead, since the bus callback can't advance lastSeenID
+	// again until this call releases mu.
+	liveFromID := t.lastSeenID
+	t.mu.Unlock()
+
+	afterID := lastEventID
+	if afterID == EarliestLastEventID {
+		afterID = ""
+	}
+
+	history, err := t.bus.History(ctx, afterID, liveFromID)
+	if err != nil {
+		return nil, err
+	}
+
+	replayed := make(chan *Update)
+	go func() {
+		defer close(replayed)
+
+		for _, update := range history {
+			if !isAuthorized(targets, update.Targets) || !isSubscribedToUpdate(topics, update.Topics) {
+				continue
+			}
+
+			select {
+			case replayed <- update:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		for update := range updateChan {
+			select {
+			case replayed <- update:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return replayed, nil
+}
+
+// Close implements Transport.
+func (t *ClusterTransport) Close() error {
+	t.local.Close()
+	return t.bus.Close()
+}
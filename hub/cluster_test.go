@@ -0,0 +1,152 @@
+package hub
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// fakeClusterBus is an in-process ClusterBus that assigns IDs atomically at
+// publish time, the way RedisClusterBus (stream IDs) and NATSClusterBus
+// (sequence numbers) do against their respective brokers. Delivery to
+// handlers is drained by a single goroutine in sequence order, the way a
+// single stream consumer would, rather than calling handlers inline on each
+// publishing goroutine: publish order and handler-invocation order must
+// agree, or ordered-delivery tests wouldn't actually exercise anything.
+type fakeClusterBus struct {
+	mu       sync.Mutex
+	seq      uint64
+	handlers []func(*Update)
+	history  []*Update
+
+	queue     chan *Update
+	closeOnce sync.Once
+}
+
+func newFakeClusterBus() *fakeClusterBus {
+	b := &fakeClusterBus{queue: make(chan *Update, 4096)}
+	go b.drain()
+	return b
+}
+
+func (b *fakeClusterBus) drain() {
+	for update := range b.queue {
+		b.mu.Lock()
+		handlers := append([]func(*Update){}, b.handlers...)
+		b.mu.Unlock()
+
+		for _, h := range handlers {
+			h(update)
+		}
+	}
+}
+
+func (b *fakeClusterBus) Publish(update *Update) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	update.ID = strconv.FormatUint(b.seq, 10)
+	b.history = append(b.history, update)
+	b.queue <- update
+
+	return nil
+}
+
+func (b *fakeClusterBus) Subscribe(ctx context.Context, handler func(*Update)) error {
+	b.mu.Lock()
+	b.handlers = append(b.handlers, handler)
+	b.mu.Unlock()
+
+	return nil
+}
+
+func (b *fakeClusterBus) History(ctx context.Context, afterID, upToID string) ([]*Update, error) {
+	if upToID == "" {
+		return nil, nil
+	}
+
+	var from uint64
+	if afterID != "" {
+		var err error
+		from, err = strconv.ParseUint(afterID, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	to, err := strconv.ParseUint(upToID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var result []*Update
+	for _, update := range b.history {
+		seq, err := strconv.ParseUint(update.ID, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		if seq > from && seq <= to {
+			result = append(result, update)
+		}
+	}
+
+	return result, nil
+}
+
+func (b *fakeClusterBus) Close() error {
+	b.closeOnce.Do(func() { close(b.queue) })
+	return nil
+}
+
+// TestClusterTransportPreservesPublishOrder checks that concurrent Dispatch
+// calls never produce a history whose ID order disagrees with its arrival
+// order, which would corrupt Last-Event-ID replay.
+func TestClusterTransportPreservesPublishOrder(t *testing.T) {
+	bus := newFakeClusterBus()
+
+	transport, err := NewClusterTransport(context.Background(), bus, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer transport.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := transport.Dispatch(&Update{Topics: []string{"https://example.com/a"}}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	matcher := newTopicMatcher([]*CompiledTopic{{Literal: "https://example.com/a"}})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updateChan, err := transport.Subscribe(ctx, EarliestLastEventID, matcher, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lastID uint64
+	for i := 0; i < 100; i++ {
+		update := <-updateChan
+		id, err := strconv.ParseUint(update.ID, 10, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id <= lastID {
+			t.Fatalf("history out of order: got ID %d after %d", id, lastID)
+		}
+		lastID = id
+	}
+}
@@ -0,0 +1,106 @@
+package hub
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"fmt"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+)
+
+// Hub delivers updates dispatched through its Transport to subscribers
+// connected over long-lived HTTP connections, through SubscribeHandler.
+type Hub struct {
+	transport      Transport
+	logger         Logger
+	allowAnonymous bool
+
+	subscriberJWTKey           interface{}
+	subscriberJWTSigningMethod jwt.SigningMethod
+
+	// JWTKeyResolver, when set, is called to resolve the key used to validate a
+	// subscriber's JWT, taking precedence over subscriberJWTKey/subscriberJWTSigningMethod.
+	// This is the extension point for JWKS fetching and key rotation.
+	JWTKeyResolver JWTKeyResolver
+}
+
+// Option configures a Hub created with NewHub.
+type Option func(*Hub) error
+
+// NewHub creates a Hub dispatching through transport and logging via logger,
+// configured by opts. By default subscribers must present a valid
+// mercureAuthorization cookie; use WithAllowAnonymous to relax that.
+func NewHub(transport Transport, logger Logger, opts ...Option) (*Hub, error) {
+	h := &Hub{transport: transport, logger: logger}
+
+	for _, opt := range opts {
+		if err := opt(h); err != nil {
+			return nil, err
+		}
+	}
+
+	if h.JWTKeyResolver == nil && h.subscriberJWTSigningMethod == nil {
+		return nil, fmt.Errorf("hub: no subscriber JWT key configured, use a WithSubscriberJWT* option or WithJWTKeyResolver")
+	}
+
+	return h, nil
+}
+
+// WithAllowAnonymous allows subscribing without presenting a JWT.
+func WithAllowAnonymous() Option {
+	return func(h *Hub) error {
+		h.allowAnonymous = true
+		return nil
+	}
+}
+
+// WithJWTKeyResolver sets the hook used to resolve a subscriber JWT's
+// validation key per-token, e.g. from a JWKS endpoint. It takes precedence
+// over any WithSubscriberJWT* option.
+func WithJWTKeyResolver(resolver JWTKeyResolver) Option {
+	return func(h *Hub) error {
+		h.JWTKeyResolver = resolver
+		return nil
+	}
+}
+
+// WithSubscriberJWTHMACKey configures HMAC-signed subscriber JWTs (HS256,
+// HS384 or HS512), validated against a single shared secret.
+func WithSubscriberJWTHMACKey(method *jwt.SigningMethodHMAC, key []byte) Option {
+	return func(h *Hub) error {
+		h.subscriberJWTSigningMethod = method
+		h.subscriberJWTKey = key
+		return nil
+	}
+}
+
+// WithSubscriberJWTRSAKey configures RSA-signed subscriber JWTs (RS256, RS384,
+// RS512, PS256, PS384 or PS512), validated against an RSA public key.
+func WithSubscriberJWTRSAKey(method jwt.SigningMethod, key *rsa.PublicKey) Option {
+	return func(h *Hub) error {
+		h.subscriberJWTSigningMethod = method
+		h.subscriberJWTKey = key
+		return nil
+	}
+}
+
+// WithSubscriberJWTECKey configures ECDSA-signed subscriber JWTs (ES256, ES384
+// or ES512), validated against an ECDSA public key.
+func WithSubscriberJWTECKey(method *jwt.SigningMethodECDSA, key *ecdsa.PublicKey) Option {
+	return func(h *Hub) error {
+		h.subscriberJWTSigningMethod = method
+		h.subscriberJWTKey = key
+		return nil
+	}
+}
+
+// WithSubscriberJWTEdDSAKey configures EdDSA-signed subscriber JWTs, validated
+// against an Ed25519 public key.
+func WithSubscriberJWTEdDSAKey(key ed25519.PublicKey) Option {
+	return func(h *Hub) error {
+		h.subscriberJWTSigningMethod = jwt.SigningMethodEdDSA
+		h.subscriberJWTKey = key
+		return nil
+	}
+}
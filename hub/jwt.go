@@ -0,0 +1,31 @@
+package hub
+
+import (
+	"fmt"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+)
+
+// JWTKeyResolver resolves the key to use to validate a JWT, based on the token itself.
+// It's called with the parsed but unverified token, so it can inspect the header
+// (for instance the "kid" claim) to pick the right key, e.g. from a JWKS endpoint.
+// When set on a Hub, it takes precedence over the statically configured key/algorithm.
+type JWTKeyResolver func(token *jwt.Token) (interface{}, error)
+
+// subscriberJWTKeyFunc is the jwt.Keyfunc used to validate subscriber JWTs.
+//
+// If a JWTKeyResolver is configured on the Hub, it's used to resolve the key,
+// allowing support for JWKS and key rotation. Otherwise, the key and algorithm
+// configured on the Hub are used, which can be an HMAC secret as well as an
+// RSA, ECDSA or Ed25519 public key.
+func (h *Hub) subscriberJWTKeyFunc(token *jwt.Token) (interface{}, error) {
+	if h.JWTKeyResolver != nil {
+		return h.JWTKeyResolver(token)
+	}
+
+	if token.Method.Alg() != h.subscriberJWTSigningMethod.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return h.subscriberJWTKey, nil
+}
@@ -0,0 +1,137 @@
+package hub
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+)
+
+func signToken(t *testing.T, method jwt.SigningMethod, key interface{}, targets []string) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, &claims{MercureTargets: targets})
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func newTestHub(t *testing.T, opts ...Option) *Hub {
+	t.Helper()
+
+	h, err := NewHub(NewLocalTransport(0, 0), noopLogger{}, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return h
+}
+
+type noopLogger struct{}
+
+func (noopLogger) Infow(string, ...interface{})  {}
+func (noopLogger) Errorw(string, ...interface{}) {}
+
+func TestExtractTargetsHMAC(t *testing.T) {
+	key := []byte("super-secret-key")
+	h := newTestHub(t, WithSubscriberJWTHMACKey(jwt.SigningMethodHS256, key))
+
+	token := signToken(t, jwt.SigningMethodHS256, key, []string{"foo"})
+
+	targets, ok := h.extractTargets(token)
+	if !ok {
+		t.Fatal("expected token to be valid")
+	}
+
+	if len(targets) != 1 || targets[0] != "foo" {
+		t.Fatalf("unexpected targets: %v", targets)
+	}
+}
+
+func TestExtractTargetsRSA(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := newTestHub(t, WithSubscriberJWTRSAKey(jwt.SigningMethodRS256, &priv.PublicKey))
+
+	token := signToken(t, jwt.SigningMethodRS256, priv, []string{"bar"})
+
+	if _, ok := h.extractTargets(token); !ok {
+		t.Fatal("expected RSA-signed token to be valid")
+	}
+}
+
+func TestExtractTargetsECDSA(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := newTestHub(t, WithSubscriberJWTECKey(jwt.SigningMethodES256, &priv.PublicKey))
+
+	token := signToken(t, jwt.SigningMethodES256, priv, []string{"baz"})
+
+	if _, ok := h.extractTargets(token); !ok {
+		t.Fatal("expected ECDSA-signed token to be valid")
+	}
+}
+
+// TestExtractTargetsRejectsAlgorithmConfusion guards against the classic
+// "alg confusion" attack: an attacker signs a token with HS256, hoping a naive
+// keyfunc will accept it regardless of alg. The HMAC secret used here is
+// arbitrary: an RS256-configured hub must reject any HS256 token on its alg
+// alone, before any key comes into play.
+func TestExtractTargetsRejectsAlgorithmConfusion(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := newTestHub(t, WithSubscriberJWTRSAKey(jwt.SigningMethodRS256, &priv.PublicKey))
+
+	forged := signToken(t, jwt.SigningMethodHS256, []byte("anything"), []string{"admin"})
+
+	if _, ok := h.extractTargets(forged); ok {
+		t.Fatal("expected HMAC-signed token to be rejected by an RSA-configured hub")
+	}
+}
+
+// TestExtractTargetsJWTKeyResolverTakesPrecedence checks that a configured
+// JWTKeyResolver is consulted instead of any static key, e.g. to support JWKS.
+func TestExtractTargetsJWTKeyResolverTakesPrecedence(t *testing.T) {
+	key := []byte("resolver-key")
+	resolverCalled := false
+
+	h := newTestHub(t,
+		WithSubscriberJWTHMACKey(jwt.SigningMethodHS256, []byte("static-key")),
+		WithJWTKeyResolver(func(token *jwt.Token) (interface{}, error) {
+			resolverCalled = true
+			return key, nil
+		}),
+	)
+
+	token := signToken(t, jwt.SigningMethodHS256, key, []string{"foo"})
+
+	if _, ok := h.extractTargets(token); !ok {
+		t.Fatal("expected token resolved via JWTKeyResolver to be valid")
+	}
+
+	if !resolverCalled {
+		t.Fatal("expected JWTKeyResolver to be called")
+	}
+}
+
+func TestNewHubRequiresAKeySource(t *testing.T) {
+	if _, err := NewHub(NewLocalTransport(0, 0), noopLogger{}); err == nil {
+		t.Fatal("expected NewHub to fail without a JWT key source configured")
+	}
+}
@@ -0,0 +1,292 @@
+package hub
+
+import (
+	"container/list"
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// LocalTransport is a Transport implementation that keeps updates in an in-memory
+// ring buffer. It's the default transport: simple and fast, but history is lost on
+// restart and isn't shared across hub instances.
+type LocalTransport struct {
+	sync.RWMutex
+
+	size        int
+	ttl         time.Duration
+	history     *list.List
+	lastEventID uint64
+	subscribers map[*subscription]struct{}
+	closed      chan struct{}
+}
+
+// subscriberBufferSize bounds how many live updates a subscriber can fall
+// behind by before it's disconnected rather than allowed to stall dispatch.
+// It's a small burst allowance, not a replay guarantee: once full, the
+// subscriber is dropped, not blocked on.
+const subscriberBufferSize = 64
+
+// subscription tracks one subscriber's matching criteria and its live-update
+// mailbox. live is never closed: Dispatch is the only writer to it, and closing
+// a channel out from under a concurrent sender is exactly what causes
+// send-on-closed-channel panics. Instead, done signals the sender to stop
+// trying, and it's closed exactly once by the subscriber's own goroutine.
+// dropped signals the opposite direction: it's closed exactly once, by the
+// dropSlow call that removed the subscriber from subscribers under t.Lock,
+// when the subscriber can't keep up and live is full.
+type subscription struct {
+	topics  *TopicMatcher
+	targets []string
+	live    chan *Update
+	done    chan struct{}
+	dropped chan struct{}
+}
+
+type historyEntry struct {
+	update  *Update
+	storeAt time.Time
+}
+
+// EarliestLastEventID is a sentinel Last-Event-ID meaning "replay all
+// available history", for subscribers that have never connected before but
+// still want the backlog rather than only live updates.
+const EarliestLastEventID = "earliest"
+
+// NewLocalTransport creates a LocalTransport keeping at most size updates, evicting
+// anything older than ttl. A size or ttl of 0 means unbounded.
+func NewLocalTransport(size int, ttl time.Duration) *LocalTransport {
+	return &LocalTransport{
+		size:        size,
+		ttl:         ttl,
+		history:     list.New(),
+		subscribers: make(map[*subscription]struct{}),
+		closed:      make(chan struct{}),
+	}
+}
+
+// Dispatch implements Transport.
+func (t *LocalTransport) Dispatch(update *Update) error {
+	t.Lock()
+
+	select {
+	case <-t.closed:
+		t.Unlock()
+		return ErrClosedTransport
+	default:
+	}
+
+	t.lastEventID++
+	update.ID = strconv.FormatUint(t.lastEventID, 10)
+
+	return t.storeAndFanOutLocked(update)
+}
+
+// dispatchWithID stores and fans out update without assigning it an ID, for use by
+// transports that already persist their own history and event IDs (e.g. BoltTransport).
+func (t *LocalTransport) dispatchWithID(update *Update) error {
+	t.Lock()
+
+	select {
+	case <-t.closed:
+		t.Unlock()
+		return ErrClosedTransport
+	default:
+	}
+
+	return t.storeAndFanOutLocked(update)
+}
+
+// storeAndFanOutLocked appends update to history and delivers it to matching
+// live subscribers. It must be called with t.Lock already held (by Dispatch,
+// right after assigning update.ID, or by dispatchWithID), and it releases the
+// lock itself: history is appended to in the exact order updates are
+// dispatched, which is the global cluster order too, only if ID assignment
+// and the PushBack below happen atomically under the same lock acquisition.
+// Otherwise two concurrent Dispatch calls could assign IDs 1 and 2 but append
+// them to history out of order. The transports that feed dispatchWithID from
+// elsewhere (Bolt, ClusterBus) guarantee their own IDs are assigned
+// atomically at the point of ordering (Bolt's bucket sequence, the underlying
+// broker's append offset for ClusterBus), so list position can be trusted as
+// ID order without re-parsing or comparing IDs here.
+func (t *LocalTransport) storeAndFanOutLocked(update *Update) error {
+	t.history.PushBack(&historyEntry{update: update, storeAt: time.Now()})
+	t.evict()
+
+	matching := make([]*subscription, 0, len(t.subscribers))
+	for sub := range t.subscribers {
+		if isAuthorized(sub.targets, update.Targets) && isSubscribedToUpdate(sub.topics, update.Topics) {
+			matching = append(matching, sub)
+		}
+	}
+	t.Unlock()
+
+	recordDispatch(update, len(matching) > 0)
+
+	// sub.live is buffered (subscriberBufferSize) so a subscriber that's
+	// merely bursty doesn't get dropped over one slow write, but the send
+	// here is still non-blocking: a subscriber whose buffer is still full is
+	// disconnected via dropSlow instead of stalling Dispatch, and with it
+	// every other subscriber and the publisher behind it. sub.live is never
+	// closed, so this can only race with sub.done being closed when the
+	// subscriber goes away on its own, which this select also guards against.
+	for _, sub := range matching {
+		select {
+		case sub.live <- update:
+		case <-sub.done:
+		default:
+			t.dropSlow(sub)
+		}
+	}
+
+	return nil
+}
+
+// dropSlow disconnects sub: it's removed from subscribers so future
+// dispatches skip it, and dropped is closed so its own goroutine (blocked
+// trying to forward a stale backlog to a stalled HTTP write) unwinds instead
+// of being written to again. Safe to call more than once for the same sub.
+func (t *LocalTransport) dropSlow(sub *subscription) {
+	t.Lock()
+	_, ok := t.subscribers[sub]
+	delete(t.subscribers, sub)
+	t.Unlock()
+
+	if ok {
+		close(sub.dropped)
+	}
+}
+
+// Subscribe implements Transport. A single goroutine owns the returned channel:
+// it drains matching history in order, then switches to live updates, so
+// replay is guaranteed to complete before any live update is delivered.
+func (t *LocalTransport) Subscribe(ctx context.Context, lastEventID string, topics *TopicMatcher, targets []string) (<-chan *Update, error) {
+	t.Lock()
+
+	select {
+	case <-t.closed:
+		t.Unlock()
+		return nil, ErrClosedTransport
+	default:
+	}
+
+	sub := &subscription{
+		topics:  topics,
+		targets: targets,
+		live:    make(chan *Update, subscriberBufferSize),
+		done:    make(chan struct{}),
+		dropped: make(chan struct{}),
+	}
+	t.subscribers[sub] = struct{}{}
+
+	// Find lastEventID in history by value rather than by parsing/comparing IDs
+	// numerically: IDs aren't necessarily decimal integers (e.g. RedisClusterBus
+	// uses opaque Redis stream IDs), so list position is the only ordering we can
+	// rely on across every Transport. If lastEventID isn't found (too old, already
+	// evicted, or unknown), nothing is replayed rather than risking duplicates.
+	var toReplay []*Update
+	if lastEventID != "" {
+		replaying := lastEventID == EarliestLastEventID
+		for e := t.history.Front(); e != nil; e = e.Next() {
+			entry := e.Value.(*historyEntry)
+
+			if !replaying {
+				if entry.update.ID == lastEventID {
+					replaying = true
+				}
+				continue
+			}
+
+			if isAuthorized(targets, entry.update.Targets) && isSubscribedToUpdate(topics, entry.update.Topics) {
+				toReplay = append(toReplay, entry.update)
+			}
+		}
+	}
+	t.Unlock()
+
+	out := make(chan *Update)
+	go func() {
+		defer close(out)
+		defer func() {
+			t.Lock()
+			delete(t.subscribers, sub)
+			t.Unlock()
+			close(sub.done)
+		}()
+
+		for _, update := range toReplay {
+			select {
+			case out <- update:
+			case <-ctx.Done():
+				return
+			case <-t.closed:
+				return
+			case <-sub.dropped:
+				return
+			}
+		}
+
+		for {
+			select {
+			case update := <-sub.live:
+				select {
+				case out <- update:
+				case <-ctx.Done():
+					return
+				case <-t.closed:
+					return
+				case <-sub.dropped:
+					return
+				}
+			case <-ctx.Done():
+				return
+			case <-t.closed:
+				return
+			case <-sub.dropped:
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// evict removes entries older than the TTL or beyond the configured size, must be
+// called with the lock held.
+func (t *LocalTransport) evict() {
+	if t.size > 0 {
+		for t.history.Len() > t.size {
+			t.history.Remove(t.history.Front())
+		}
+	}
+
+	if t.ttl > 0 {
+		cutoff := time.Now().Add(-t.ttl)
+		for e := t.history.Front(); e != nil; {
+			entry := e.Value.(*historyEntry)
+			if entry.storeAt.After(cutoff) {
+				break
+			}
+
+			next := e.Next()
+			t.history.Remove(e)
+			e = next
+		}
+	}
+}
+
+// Close implements Transport.
+func (t *LocalTransport) Close() error {
+	t.Lock()
+	defer t.Unlock()
+
+	select {
+	case <-t.closed:
+		return nil
+	default:
+		close(t.closed)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package hub
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestLocalTransportDispatchDuringDisconnect exercises concurrent dispatch and
+// subscriber disconnection: Dispatch must never send on a channel the
+// subscriber side has closed. Run with `go test -race` to catch the panic this
+// guards against.
+func TestLocalTransportDispatchDuringDisconnect(t *testing.T) {
+	transport := NewLocalTransport(0, 0)
+	defer transport.Close()
+
+	matcher := newTopicMatcher([]*CompiledTopic{{Literal: "https://example.com/a"}})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			updateChan, err := transport.Subscribe(ctx, "", matcher, nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			go func() {
+				time.Sleep(time.Millisecond)
+				cancel()
+			}()
+
+			for range updateChan {
+			}
+		}()
+	}
+
+	for i := 0; i < 200; i++ {
+		if err := transport.Dispatch(&Update{Topics: []string{"https://example.com/a"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wg.Wait()
+}
+
+// TestLocalTransportReplaysHistoryBeforeLiveUpdates checks that an update
+// dispatched concurrently with a replaying subscription is never delivered
+// ahead of the backlog the subscription asked to replay.
+func TestLocalTransportReplaysHistoryBeforeLiveUpdates(t *testing.T) {
+	transport := NewLocalTransport(0, 0)
+	defer transport.Close()
+
+	topic := "https://example.com/a"
+	matcher := newTopicMatcher([]*CompiledTopic{{Literal: topic}})
+
+	const historyCount = 100
+	for i := 0; i < historyCount; i++ {
+		if err := transport.Dispatch(&Update{Topics: []string{topic}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updateChan, err := transport.Subscribe(ctx, EarliestLastEventID, matcher, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Dispatch a live update right away: it must still arrive after every
+	// replayed historical update, never interleaved ahead of them.
+	go func() {
+		if err := transport.Dispatch(&Update{Topics: []string{topic}}); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	lastID := uint64(0)
+	for i := 0; i < historyCount+1; i++ {
+		select {
+		case update := <-updateChan:
+			id, err := strconv.ParseUint(update.ID, 10, 64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if id <= lastID {
+				t.Fatalf("received update %s out of order after %d", update.ID, lastID)
+			}
+			lastID = id
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for update")
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package hub
+
+// Logger is the structured logging interface used throughout the hub. Its
+// shape matches zap.SugaredLogger and zerolog.Logger closely enough that
+// either can be plugged in as Hub.logger via a thin adapter, so operators
+// aren't locked into one logging library.
+type Logger interface {
+	Infow(msg string, keysAndValues ...interface{})
+	Errorw(msg string, keysAndValues ...interface{})
+}
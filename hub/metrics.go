@@ -0,0 +1,59 @@
+package hub
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	subscribersConnected = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "mercure_subscribers_connected",
+		Help: "The current number of subscribers connected to the hub.",
+	})
+
+	// updatesDispatchedTotal is intentionally not labelled by topic: Mercure
+	// topics are typically per-resource URIs, so a topic label would give this
+	// metric unbounded cardinality in any real deployment.
+	updatesDispatchedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "mercure_updates_dispatched_total",
+		Help: "The total number of updates dispatched, by whether they matched at least one currently-connected, authorized subscriber.",
+	}, []string{"delivered"})
+
+	updateDeliveryDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name: "mercure_update_delivery_duration_seconds",
+		Help: "Time spent writing an update to a subscriber's connection.",
+	})
+
+	jwtValidationErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "mercure_jwt_validation_errors_total",
+		Help: "The total number of subscriber JWT validation errors, by reason.",
+	}, []string{"reason"})
+)
+
+// recordDispatch increments mercure_updates_dispatched_total, labelled with
+// whether update matched at least one currently-connected subscriber, i.e. one
+// subscribed to one of its topics and authorized for its targets. The label
+// does not say whether the update itself is public: a fully public update
+// dispatched while nobody is connected is still "delivered=false", since
+// nothing was there to receive it.
+func recordDispatch(update *Update, delivered bool) {
+	label := "false"
+	if delivered {
+		label = "true"
+	}
+
+	updatesDispatchedTotal.WithLabelValues(label).Inc()
+}
+
+// ServeMetrics starts a dedicated HTTP listener exposing Prometheus metrics on
+// addr, kept separate from the public-facing hub listener so that the metrics
+// endpoint can be firewalled off independently.
+func ServeMetrics(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	return http.ListenAndServe(addr, mux)
+}
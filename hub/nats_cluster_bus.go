@@ -0,0 +1,137 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSClusterBus is a ClusterBus backed by a NATS JetStream stream.
+type NATSClusterBus struct {
+	js      nats.JetStreamContext
+	stream  string
+	subject string
+	sub     *nats.Subscription
+}
+
+// NewNATSClusterBus creates a ClusterBus publishing updates on subject, which
+// must be captured by the named JetStream stream. The stream name is needed
+// separately from the subject so History can fetch messages directly by
+// sequence number.
+func NewNATSClusterBus(js nats.JetStreamContext, stream, subject string) *NATSClusterBus {
+	return &NATSClusterBus{js: js, stream: stream, subject: subject}
+}
+
+// Publish implements ClusterBus. It sets update.ID to the JetStream stream
+// sequence number assigned by the broker's publish ack, which is atomic and
+// monotonically increasing cluster-wide, so ID order always matches stream
+// order.
+func (b *NATSClusterBus) Publish(update *Update) error {
+	data, err := json.Marshal(update)
+	if err != nil {
+		return err
+	}
+
+	ack, err := b.js.Publish(b.subject, data)
+	if err != nil {
+		return err
+	}
+
+	update.ID = strconv.FormatUint(ack.Sequence, 10)
+
+	return nil
+}
+
+// Subscribe implements ClusterBus, setting each update's ID from the stream
+// sequence number in the message's JetStream metadata, so it's consistent with
+// the ID Publish assigned on whichever node published it. History below
+// serves backlog older than this subscription.
+func (b *NATSClusterBus) Subscribe(ctx context.Context, handler func(*Update)) error {
+	// DeliverNew matches RedisClusterBus's "$"-based tail read: a node joining
+	// the cluster picks up updates published from here on, not the stream's
+	// entire retained history.
+	sub, err := b.js.Subscribe(b.subject, func(msg *nats.Msg) {
+		var update Update
+		if err := json.Unmarshal(msg.Data, &update); err != nil {
+			return
+		}
+
+		if meta, err := msg.Metadata(); err == nil {
+			update.ID = strconv.FormatUint(meta.Sequence.Stream, 10)
+		}
+
+		handler(&update)
+	}, nats.DeliverNew())
+	if err != nil {
+		return err
+	}
+
+	b.sub = sub
+
+	go func() {
+		<-ctx.Done()
+		sub.Unsubscribe()
+	}()
+
+	return nil
+}
+
+// History implements ClusterBus by fetching messages directly from the
+// JetStream stream by sequence number, so replay doesn't depend on this node
+// having been subscribed at the time an update was published.
+func (b *NATSClusterBus) History(ctx context.Context, afterID, upToID string) ([]*Update, error) {
+	if upToID == "" {
+		return nil, nil
+	}
+
+	var from uint64
+	if afterID != "" {
+		var err error
+		from, err = strconv.ParseUint(afterID, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	to, err := strconv.ParseUint(upToID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	var updates []*Update
+	for seq := from + 1; seq <= to; seq++ {
+		select {
+		case <-ctx.Done():
+			return updates, ctx.Err()
+		default:
+		}
+
+		msg, err := b.js.GetMsg(b.stream, seq)
+		if err != nil {
+			// Already pruned by the stream's own retention policy: same as
+			// BoltTransport replaying less than asked for, not a hard failure.
+			continue
+		}
+
+		var update Update
+		if err := json.Unmarshal(msg.Data, &update); err != nil {
+			continue
+		}
+
+		update.ID = strconv.FormatUint(seq, 10)
+		updates = append(updates, &update)
+	}
+
+	return updates, nil
+}
+
+// Close implements ClusterBus.
+func (b *NATSClusterBus) Close() error {
+	if b.sub != nil {
+		return b.sub.Unsubscribe()
+	}
+
+	return nil
+}
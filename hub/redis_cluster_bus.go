@@ -0,0 +1,166 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// redisRetryBackoff bounds how fast Subscribe retries XRead after a transient
+// error (e.g. the connection dropping), so a persistent failure (connection
+// refused, auth failure) can't spin the goroutine at full CPU.
+const redisRetryBackoff = 500 * time.Millisecond
+
+// RedisClusterBus is a ClusterBus backed by a Redis stream, used to fan updates
+// out across hub instances.
+type RedisClusterBus struct {
+	client     *redis.Client
+	stream     string
+	background context.Context
+	cancel     context.CancelFunc
+}
+
+// NewRedisClusterBus creates a ClusterBus publishing updates on the given Redis
+// stream key.
+func NewRedisClusterBus(client *redis.Client, stream string) *RedisClusterBus {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &RedisClusterBus{
+		client:     client,
+		stream:     stream,
+		background: ctx,
+		cancel:     cancel,
+	}
+}
+
+// Publish implements ClusterBus. It sets update.ID to the Redis stream entry ID
+// XADD assigns, which Redis guarantees is monotonically increasing and
+// assigned atomically at append time, so ID order always matches stream order
+// cluster-wide.
+func (b *RedisClusterBus) Publish(update *Update) error {
+	data, err := json.Marshal(update)
+	if err != nil {
+		return err
+	}
+
+	id, err := b.client.XAdd(b.background, &redis.XAddArgs{
+		Stream: b.stream,
+		Values: map[string]interface{}{"update": data},
+	}).Result()
+	if err != nil {
+		return err
+	}
+
+	update.ID = id
+
+	return nil
+}
+
+// Subscribe implements ClusterBus, reading the stream from its current tail so
+// every node only receives updates published after it joined; History below
+// serves backlog older than that. The ID handler receives on each update is
+// the same Redis-assigned stream ID set by Publish, so it stays consistent
+// cluster-wide.
+func (b *RedisClusterBus) Subscribe(ctx context.Context, handler func(*Update)) error {
+	go func() {
+		lastID := "$"
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-b.background.Done():
+				return
+			default:
+			}
+
+			res, err := b.client.XRead(ctx, &redis.XReadArgs{
+				Streams: []string{b.stream, lastID},
+				Block:   0,
+			}).Result()
+			if err != nil {
+				// ctx/background being done surfaces here as a client error too
+				// (context canceled mid-block); don't backoff on our own shutdown.
+				if ctx.Err() != nil || b.background.Err() != nil {
+					return
+				}
+
+				select {
+				case <-time.After(redisRetryBackoff):
+				case <-ctx.Done():
+					return
+				case <-b.background.Done():
+					return
+				}
+
+				continue
+			}
+
+			for _, stream := range res {
+				for _, message := range stream.Messages {
+					lastID = message.ID
+
+					raw, ok := message.Values["update"].(string)
+					if !ok {
+						continue
+					}
+
+					var update Update
+					if err := json.Unmarshal([]byte(raw), &update); err != nil {
+						continue
+					}
+
+					update.ID = message.ID
+					handler(&update)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// History implements ClusterBus by reading the stream directly with XRANGE,
+// so replay doesn't depend on this node having been subscribed at the time an
+// update was published.
+func (b *RedisClusterBus) History(ctx context.Context, afterID, upToID string) ([]*Update, error) {
+	if upToID == "" {
+		return nil, nil
+	}
+
+	start := "-"
+	if afterID != "" {
+		start = "(" + afterID
+	}
+
+	entries, err := b.client.XRange(ctx, b.stream, start, upToID).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make([]*Update, 0, len(entries))
+	for _, entry := range entries {
+		raw, ok := entry.Values["update"].(string)
+		if !ok {
+			continue
+		}
+
+		var update Update
+		if err := json.Unmarshal([]byte(raw), &update); err != nil {
+			return nil, err
+		}
+
+		update.ID = entry.ID
+		updates = append(updates, &update)
+	}
+
+	return updates, nil
+}
+
+// Close implements ClusterBus.
+func (b *RedisClusterBus) Close() error {
+	b.cancel()
+	return b.client.Close()
+}
@@ -0,0 +1,96 @@
+package hub
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/quic-go/quic-go/http3"
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// ServerConfig configures how the hub's HTTP server is started.
+type ServerConfig struct {
+	// Addr is the TCP address the server listens on, e.g. ":443".
+	Addr string
+
+	// AllowHTTP allows serving h2c (HTTP/2 without TLS) on Addr, useful behind a
+	// TLS-terminating proxy. When false, TLS (and so HTTP/2 over TLS) is required.
+	AllowHTTP bool
+
+	// AutocertManager, when set, is used to obtain and renew a TLS certificate
+	// automatically via ACME/Let's Encrypt instead of using static cert files.
+	AutocertManager *autocert.Manager
+
+	// HTTP3 enables serving the hub over HTTP/3 (QUIC) on the same Addr, in
+	// addition to HTTP/1.1 and HTTP/2. Requires AutocertManager, the only source
+	// of the TLS config QUIC needs.
+	HTTP3 bool
+}
+
+// ListenAndServe starts the hub's HTTP server according to config, serving mux
+// (which routes to SubscribeHandler) over HTTP/1.1, HTTP/2 (h2/h2c) and,
+// optionally, HTTP/3.
+func (h *Hub) ListenAndServe(mux http.Handler, config ServerConfig) error {
+	server := &http.Server{
+		Addr:    config.Addr,
+		Handler: mux,
+	}
+
+	if config.AllowHTTP {
+		server.Handler = h2c.NewHandler(mux, &http2.Server{})
+	}
+
+	if config.AutocertManager != nil {
+		server.TLSConfig = config.AutocertManager.TLSConfig()
+	}
+
+	if server.TLSConfig == nil && !config.AllowHTTP {
+		return fmt.Errorf("hub: TLS is required unless AllowHTTP is set, configure AutocertManager")
+	}
+
+	if err := http2.ConfigureServer(server, &http2.Server{}); err != nil {
+		return err
+	}
+
+	if config.HTTP3 {
+		http3Server := &http3.Server{Server: server}
+
+		go func() {
+			log.Printf("serving HTTP/3 on %s", config.Addr)
+			if err := http3Server.ListenAndServe(); err != nil {
+				log.Printf("HTTP/3 server stopped: %s", err)
+			}
+		}()
+
+		server.Handler = advertiseHTTP3(server.Handler, config.Addr)
+	}
+
+	if server.TLSConfig != nil {
+		return server.ListenAndServeTLS("", "")
+	}
+
+	return server.ListenAndServe()
+}
+
+// advertiseHTTP3 adds an Alt-Svc header so that clients connecting over
+// HTTP/1.1 or HTTP/2 learn they can upgrade to HTTP/3 on their next connection.
+func advertiseHTTP3(next http.Handler, addr string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Alt-Svc", fmt.Sprintf(`h3=":%s"; ma=3600`, portOf(addr)))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// portOf extracts the port from a host:port address, defaulting to 443.
+func portOf(addr string) string {
+	for i := len(addr) - 1; i >= 0; i-- {
+		if addr[i] == ':' {
+			return addr[i+1:]
+		}
+	}
+
+	return "443"
+}
@@ -4,15 +4,14 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"regexp"
+	"time"
 
-	jwt "github.com/dgrijalva/jwt-go"
-	"github.com/yosida95/uritemplate"
+	jwt "github.com/golang-jwt/jwt/v5"
 )
 
 type claims struct {
 	MercureTargets []string `json:"mercureTargets"`
-	jwt.StandardClaims
+	jwt.RegisteredClaims
 }
 
 // SubscribeHandler create a keep alive connection and send the events to the subscribers
@@ -24,6 +23,8 @@ func (h *Hub) SubscribeHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ctx := r.Context()
+
 	targets := []string{}
 	cookie, err := r.Cookie("mercureAuthorization")
 	if err == nil {
@@ -42,61 +43,67 @@ func (h *Hub) SubscribeHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var regexps = make([]*regexp.Regexp, len(topics))
+	compiledTopics := make([]*CompiledTopic, len(topics))
 	for index, topic := range topics {
-		tpl, err := uritemplate.New(topic)
-		if nil != err {
+		compiled, err := compileTopic(topic)
+		if err != nil {
 			http.Error(w, fmt.Sprintf("\"%s\" is not a valid URI template (RFC6570).", topic), http.StatusBadRequest)
 			return
 		}
-		regexps[index] = tpl.Regexp()
+		compiledTopics[index] = compiled
 	}
+	matcher := newTopicMatcher(compiledTopics)
 
-	log.Printf("%s connected.", r.RemoteAddr)
+	h.logger.Infow("subscriber connected", "remote_addr", r.RemoteAddr)
 	sendHeaders(w)
 
-	// Create a new channel, over which the hub can send can send updates to this subscriber.
-	updateChan := make(chan Update)
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventID")
+	}
 
-	// Add this client to the map of those that should
-	// receive updates
-	h.newSubscribers <- updateChan
+	updateChan, err := h.transport.Subscribe(ctx, lastEventID, matcher, targets)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
 
-	// Listen to the closing of the http connection via the CloseNotifier
-	notify := w.(http.CloseNotifier).CloseNotify()
-	go func() {
-		<-notify
-		h.removedSubscribers <- updateChan
-		log.Printf("%s disconnected.", r.RemoteAddr)
-	}()
+	subscribersConnected.Inc()
+	defer subscribersConnected.Dec()
 
+	// The request's context is canceled when the underlying connection is closed,
+	// which works uniformly across HTTP/1.1, HTTP/2 and HTTP/3, unlike the
+	// deprecated http.CloseNotifier.
 	for {
-		update, open := <-updateChan
-		if !open {
-			break
-		}
+		select {
+		case update, open := <-updateChan:
+			if !open {
+				return
+			}
 
-		// Check authorization
-		if !isAuthorized(targets, update.Targets) || !isSubscribedToUpdate(regexps, update.Topics) {
-			continue
+			start := time.Now()
+			// update is shared with other subscribers replaying or receiving the
+			// same history entry, so copy Event rather than mutating it in place.
+			// The transport, not the publisher, owns the replay ID: stamp it onto
+			// the copy so the browser echoes it back as Last-Event-ID.
+			event := update.Event
+			event.ID = update.ID
+			fmt.Fprint(w, event.String())
+			f.Flush()
+			updateDeliveryDuration.Observe(time.Since(start).Seconds())
+		case <-ctx.Done():
+			h.logger.Infow("subscriber disconnected", "remote_addr", r.RemoteAddr)
+			return
 		}
-
-		fmt.Fprint(w, update.Event.String())
-
-		f.Flush()
 	}
 }
 
 // extractTargets extracts the subscriber's authorized targets from the JWT
 func (h *Hub) extractTargets(encodedToken string) ([]string, bool) {
-	token, err := jwt.ParseWithClaims(encodedToken, &claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
-		}
-		return h.subscriberJWTKey, nil
-	})
+	token, err := jwt.ParseWithClaims(encodedToken, &claims{}, h.subscriberJWTKeyFunc)
 
 	if err != nil {
+		jwtValidationErrorsTotal.WithLabelValues("parse_error").Inc()
 		return nil, false
 	}
 
@@ -104,6 +111,8 @@ func (h *Hub) extractTargets(encodedToken string) ([]string, bool) {
 		return claims.MercureTargets, true
 	}
 
+	jwtValidationErrorsTotal.WithLabelValues("invalid_token").Inc()
+
 	return nil, false
 }
 
@@ -140,15 +149,6 @@ func isAuthorized(subscriberTargets []string, updateTargets map[string]struct{})
 }
 
 // isSubscribedToUpdate checks if the subscriber has subscribed to this update
-func isSubscribedToUpdate(regexps []*regexp.Regexp, topics []string) bool {
-	// Add a global cache here
-	for _, r := range regexps {
-		for _, t := range topics {
-			if r.MatchString(t) {
-				return true
-			}
-		}
-	}
-
-	return false
-}
\ No newline at end of file
+func isSubscribedToUpdate(matcher *TopicMatcher, topics []string) bool {
+	return matcher.Matches(topics)
+}
@@ -0,0 +1,92 @@
+package hub
+
+import (
+	"regexp"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/yosida95/uritemplate"
+)
+
+// templateCacheSize bounds the number of distinct topic templates kept compiled
+// in memory; topics beyond that are recompiled on a cache miss.
+const templateCacheSize = 5000
+
+// templateCache caches compiled URI templates keyed by their raw template
+// string, so that recurring topic subscriptions don't pay the parsing and
+// regexp-compilation cost on every connection.
+var templateCache, _ = lru.New(templateCacheSize)
+
+// CompiledTopic is a topic template that has been parsed once. Literal is set
+// to the topic's raw string when it contains no URI template variables, so
+// that matching against it can skip the regexp engine entirely.
+type CompiledTopic struct {
+	Literal string
+	Regexp  *regexp.Regexp
+}
+
+// compileTopic parses topic as an RFC6570 URI template, serving the result from
+// templateCache when available.
+func compileTopic(topic string) (*CompiledTopic, error) {
+	if cached, ok := templateCache.Get(topic); ok {
+		return cached.(*CompiledTopic), nil
+	}
+
+	tpl, err := uritemplate.New(topic)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled := &CompiledTopic{Regexp: tpl.Regexp()}
+	if len(tpl.Varnames()) == 0 {
+		compiled.Literal = topic
+	}
+
+	templateCache.Add(topic, compiled)
+
+	return compiled, nil
+}
+
+// TopicMatcher tests whether an update's topics satisfy a subscriber's set of
+// topic templates. It's built once per connection and reused for every update
+// delivered on that connection, splitting literal topics (matched via a map
+// lookup) from templated ones (matched via regexp) so the common case of plain
+// topic strings never touches the regexp engine.
+type TopicMatcher struct {
+	literals map[string]struct{}
+	regexps  []*regexp.Regexp
+}
+
+// newTopicMatcher builds a TopicMatcher from a subscriber's compiled topics.
+func newTopicMatcher(topics []*CompiledTopic) *TopicMatcher {
+	tm := &TopicMatcher{literals: make(map[string]struct{}, len(topics))}
+
+	for _, topic := range topics {
+		if topic.Literal != "" {
+			tm.literals[topic.Literal] = struct{}{}
+			continue
+		}
+
+		tm.regexps = append(tm.regexps, topic.Regexp)
+	}
+
+	return tm
+}
+
+// Matches reports whether any of updateTopics satisfies this matcher.
+func (tm *TopicMatcher) Matches(updateTopics []string) bool {
+	for _, t := range updateTopics {
+		if _, ok := tm.literals[t]; ok {
+			return true
+		}
+	}
+
+	for _, r := range tm.regexps {
+		for _, t := range updateTopics {
+			if r.MatchString(t) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
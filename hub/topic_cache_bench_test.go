@@ -0,0 +1,72 @@
+package hub
+
+import (
+	"fmt"
+	"testing"
+)
+
+// buildSubscribers creates n TopicMatchers, half subscribed to a literal topic
+// and half to a templated one, simulating a mix of plain and RFC6570 topics.
+func buildSubscribers(n int) []*TopicMatcher {
+	matchers := make([]*TopicMatcher, n)
+
+	for i := 0; i < n; i++ {
+		if i%2 == 0 {
+			matchers[i] = newTopicMatcher([]*CompiledTopic{{Literal: "https://example.com/books/1"}})
+			continue
+		}
+
+		tpl, _ := compileTopic("https://example.com/books/{id}")
+		matchers[i] = newTopicMatcher([]*CompiledTopic{tpl})
+	}
+
+	return matchers
+}
+
+// BenchmarkIsSubscribedToUpdate_Literal measures matching throughput when every
+// subscriber holds a literal (non-templated) topic.
+func BenchmarkIsSubscribedToUpdate_Literal(b *testing.B) {
+	matcher := newTopicMatcher([]*CompiledTopic{{Literal: "https://example.com/books/1"}})
+	topics := []string{"https://example.com/books/1"}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		isSubscribedToUpdate(matcher, topics)
+	}
+}
+
+// BenchmarkIsSubscribedToUpdate_10kSubscribers simulates dispatching a single
+// update to 10k concurrent subscribers with a literal/templated topic mix.
+func BenchmarkIsSubscribedToUpdate_10kSubscribers(b *testing.B) {
+	matchers := buildSubscribers(10000)
+	topics := []string{"https://example.com/books/1"}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, m := range matchers {
+			isSubscribedToUpdate(m, topics)
+		}
+	}
+}
+
+// BenchmarkCompileTopic_Cached measures the cost of resolving a topic template
+// that's already in templateCache, as happens on every reconnect.
+func BenchmarkCompileTopic_Cached(b *testing.B) {
+	topic := "https://example.com/books/{id}"
+	if _, err := compileTopic(topic); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := compileTopic(topic); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func ExampleCompiledTopic() {
+	compiled, _ := compileTopic("https://example.com/books/1")
+	fmt.Println(compiled.Literal)
+	// Output: https://example.com/books/1
+}
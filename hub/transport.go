@@ -0,0 +1,34 @@
+package hub
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrClosedTransport is returned by Transport methods after Close has been called.
+var ErrClosedTransport = errors.New("hub: transport is closed")
+
+// Transport persists updates and dispatches them to subscribers, live and from history.
+//
+// Implementations are responsible for assigning each update its ID (overwriting
+// any ID already set) and for making past updates available to Subscribe via the lastEventID
+// parameter, so that reconnecting subscribers don't miss updates sent while they
+// were offline.
+type Transport interface {
+	// Dispatch persists the update and delivers it to currently active subscriptions
+	// matching its topics and targets.
+	Dispatch(update *Update) error
+
+	// Subscribe returns a channel of updates matching the given topics and targets.
+	// If lastEventID is not empty, every matching update stored with an ID strictly
+	// greater than lastEventID is replayed on the channel before live updates.
+	// An implementation that can't place lastEventID in its history replays
+	// nothing: LocalTransport when the ID is no longer in its buffer,
+	// BoltTransport when the ID isn't one of its own.
+	// The returned channel is closed when ctx is done or the transport is closed.
+	Subscribe(ctx context.Context, lastEventID string, topics *TopicMatcher, targets []string) (<-chan *Update, error)
+
+	// Close closes the transport. Subsequent calls to Dispatch and Subscribe return
+	// ErrClosedTransport.
+	Close() error
+}
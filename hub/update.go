@@ -0,0 +1,49 @@
+package hub
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Event represents a server-sent event to dispatch to subscribers.
+// See https://developer.mozilla.org/en-US/docs/Web/API/Server-sent_events/Using_server-sent_events#Event_stream_format
+type Event struct {
+	ID    string
+	Type  string
+	Retry uint64
+	Data  string
+}
+
+// String formats the event following the server-sent events specification.
+func (e Event) String() string {
+	message := ""
+
+	if e.ID != "" {
+		message += "id: " + e.ID + "\n"
+	}
+
+	if e.Type != "" {
+		message += "event: " + e.Type + "\n"
+	}
+
+	if e.Retry > 0 {
+		message += fmt.Sprintf("retry: %d\n", e.Retry)
+	}
+
+	for _, line := range strings.Split(e.Data, "\n") {
+		message += "data: " + line + "\n"
+	}
+
+	return message + "\n"
+}
+
+// Update represents an update to dispatch to subscribers, it's also stored in the history.
+type Update struct {
+	// ID uniquely identifies this update in the history, it must be monotonically
+	// orderable so that Last-Event-ID replay can select everything strictly after it.
+	ID string
+
+	Event   Event
+	Topics  []string
+	Targets map[string]struct{}
+}
@@ -0,0 +1,19 @@
+package hub
+
+import "go.uber.org/zap"
+
+// ZapLogger adapts a *zap.SugaredLogger to the Logger interface.
+type ZapLogger struct {
+	*zap.SugaredLogger
+}
+
+// NewZapLogger builds a Logger backed by zap's production configuration, to
+// pass to NewHub.
+func NewZapLogger() (*ZapLogger, error) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ZapLogger{SugaredLogger: logger.Sugar()}, nil
+}